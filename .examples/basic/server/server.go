@@ -17,7 +17,8 @@ func main() {
 	c.AppId = protobuf.AppId
 
 	s := krpc.Server.NewGrpcServer(c)
-	protobuf.RegisterEchoServer(s.Server, new(service.Echo))
-	protobuf.RegisterTimeServer(s.Server, new(service.Time))
-	s.Run()
+	protobuf.RegisterEchoServer(s, new(service.Echo))
+	protobuf.RegisterTimeServer(s, new(service.Time))
+	s.Start()
+	s.Wait()
 }
\ No newline at end of file
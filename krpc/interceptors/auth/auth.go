@@ -0,0 +1,73 @@
+// Package auth provides per-RPC authentication interceptors, modeled after
+// go-grpc-middleware's auth package: an AuthFunc extracts and verifies
+// credentials from the incoming context before the handler runs.
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AuthFunc authenticates ctx, typically by extracting and verifying a token
+// obtained via AuthFromMD, and returns a context augmented with the
+// resolved identity, or an error to reject the call.
+type AuthFunc func(ctx context.Context) (context.Context, error)
+
+// AuthFromMD extracts the value following scheme (case-insensitively) from
+// the "authorization" metadata header, e.g. AuthFromMD(ctx, "bearer") reads
+// a "Bearer <token>" header and returns "<token>".
+func AuthFromMD(ctx context.Context, scheme string) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "auth: no metadata in context")
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", status.Error(codes.Unauthenticated, "auth: authorization header not found")
+	}
+	splits := strings.SplitN(values[0], " ", 2)
+	if len(splits) < 2 || !strings.EqualFold(splits[0], scheme) {
+		return "", status.Errorf(codes.Unauthenticated, "auth: authorization header does not use %s scheme", scheme)
+	}
+	return splits[1], nil
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that
+// authenticates every call with fn before invoking the handler.
+func UnaryServerInterceptor(fn AuthFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		newCtx, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(newCtx, req)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// authenticates the stream with fn before invoking the handler.
+func StreamServerInterceptor(fn AuthFunc) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		newCtx, err := fn(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authServerStream{ServerStream: ss, ctx: newCtx})
+	}
+}
+
+// authServerStream overrides Context so downstream handlers see the
+// context produced by AuthFunc.
+type authServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,80 @@
+package ban
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/database/gredis"
+	"github.com/gogf/gf/frame/g"
+)
+
+// redisKeyPrefix namespaces ban entries within the shared Redis keyspace so
+// they don't collide with other cluster-wide state such as discovery's.
+const redisKeyPrefix = "katyusha:ban:"
+
+// RedisStore is a Store backed by Redis, so that a ban pushed from one
+// instance (e.g. after repeated auth failures) takes effect cluster-wide,
+// the same way discovery uses etcd to share service registrations.
+// Standing rules (CIDR blocks, predicates) are process-local, matching
+// MemoryStore's semantics, since those are normally supplied at startup on
+// every instance rather than pushed at runtime.
+type RedisStore struct {
+	redis *gredis.Redis
+
+	mu    sync.RWMutex
+	rules []Rule
+}
+
+// NewRedisStore returns a RedisStore using the given Redis client.
+func NewRedisStore(redis *gredis.Redis) *RedisStore {
+	return &RedisStore{redis: redis}
+}
+
+// AddRule implements Store.
+func (s *RedisStore) AddRule(rule Rule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = append(s.rules, rule)
+}
+
+// Ban implements Store.
+func (s *RedisStore) Ban(key string, ttl time.Duration, reason string) {
+	if reason == "" {
+		reason = "banned"
+	}
+	var err error
+	if ttl > 0 {
+		// PX takes milliseconds, so sub-second ttl values (which truncate to
+		// 0 under EX's whole-second granularity, and SET ... EX 0 is
+		// rejected by Redis) still produce a valid, positive expiry.
+		ms := ttl.Milliseconds()
+		if ms == 0 {
+			ms = 1
+		}
+		_, err = s.redis.Do("SET", redisKeyPrefix+key, reason, "PX", ms)
+	} else {
+		_, err = s.redis.Do("SET", redisKeyPrefix+key, reason)
+	}
+	if err != nil {
+		g.Log().Errorf("ban: failed to store ban for %q: %v", key, err)
+	}
+}
+
+// Banned implements Store.
+func (s *RedisStore) Banned(ip net.IP) (string, bool) {
+	key := ip.String()
+	value, err := s.redis.DoVar("GET", redisKeyPrefix+key)
+	if err == nil && !value.IsEmpty() {
+		return value.String(), true
+	}
+	s.mu.RLock()
+	rules := s.rules
+	s.mu.RUnlock()
+	for _, rule := range rules {
+		if rule.Match(ip) {
+			return "matched standing rule", true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,115 @@
+package ban
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// Rule decides whether ip should be banned, independent of any TTL-based
+// entries a Store may also hold. Implementations may match a single IP, a
+// CIDR range, or apply arbitrary predicate logic (e.g. ASN or geo lookups).
+type Rule interface {
+	Match(ip net.IP) bool
+}
+
+// RuleFunc adapts a plain function to Rule.
+type RuleFunc func(ip net.IP) bool
+
+// Match implements Rule.
+func (f RuleFunc) Match(ip net.IP) bool { return f(ip) }
+
+// IPRule bans a single exact IP address.
+func IPRule(ip string) Rule {
+	banned := net.ParseIP(ip)
+	return RuleFunc(func(ip net.IP) bool {
+		return banned != nil && banned.Equal(ip)
+	})
+}
+
+// CIDRRule bans every IP within cidr.
+func CIDRRule(cidr string) Rule {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return RuleFunc(func(net.IP) bool { return false })
+	}
+	return RuleFunc(func(ip net.IP) bool {
+		return network.Contains(ip)
+	})
+}
+
+// Store manages the rules and temporary bans consulted on every RPC. The
+// default MemoryStore is process-local; RedisStore shares state across a
+// cluster the way discovery already shares service registrations through
+// etcd.
+type Store interface {
+	// AddRule adds a standing rule, e.g. a CIDR block, with no expiry.
+	AddRule(rule Rule)
+	// Ban temporarily bans key (typically a client IP string) for ttl,
+	// recording reason for diagnostics. A zero ttl bans indefinitely.
+	Ban(key string, ttl time.Duration, reason string)
+	// Banned reports whether ip is currently banned, either by a standing
+	// Rule or by a temporary ban registered through Ban.
+	Banned(ip net.IP) (reason string, banned bool)
+}
+
+// MemoryStore is an in-memory Store suitable for single-instance
+// deployments or as the local cache in front of a shared Store.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	rules []Rule
+	bans  map[string]banEntry
+}
+
+type banEntry struct {
+	reason   string
+	deadline time.Time // zero means no expiry
+}
+
+// NewMemoryStore creates and returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		bans: make(map[string]banEntry),
+	}
+}
+
+// AddRule implements Store.
+func (s *MemoryStore) AddRule(rule Rule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = append(s.rules, rule)
+}
+
+// Ban implements Store.
+func (s *MemoryStore) Ban(key string, ttl time.Duration, reason string) {
+	entry := banEntry{reason: reason}
+	if ttl > 0 {
+		entry.deadline = time.Now().Add(ttl)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bans[key] = entry
+}
+
+// Banned implements Store.
+func (s *MemoryStore) Banned(ip net.IP) (string, bool) {
+	key := ip.String()
+	s.mu.RLock()
+	entry, ok := s.bans[key]
+	rules := s.rules
+	s.mu.RUnlock()
+	if ok {
+		if entry.deadline.IsZero() || time.Now().Before(entry.deadline) {
+			return entry.reason, true
+		}
+		s.mu.Lock()
+		delete(s.bans, key)
+		s.mu.Unlock()
+	}
+	for _, rule := range rules {
+		if rule.Match(ip) {
+			return "matched standing rule", true
+		}
+	}
+	return "", false
+}
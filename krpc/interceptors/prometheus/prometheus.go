@@ -0,0 +1,62 @@
+// Package prometheus provides gRPC server interceptors that record
+// per-method, per-code request counts and latency histograms, modeled
+// after go-grpc-middleware's prometheus package.
+package prometheus
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	handledTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grpc",
+		Subsystem: "server",
+		Name:      "handled_total",
+		Help:      "Total number of completed RPCs, by method and status code.",
+	}, []string{"grpc_method", "grpc_code"})
+
+	handledSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grpc",
+		Subsystem: "server",
+		Name:      "handling_seconds",
+		Help:      "Histogram of response latency of RPCs, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"grpc_method"})
+)
+
+func init() {
+	prometheus.MustRegister(handledTotal, handledSeconds)
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that records
+// a handled_total and handling_seconds observation for every call.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		record(info.FullMethod, err, start)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// records a handled_total and handling_seconds observation for every
+// stream, measured over its full lifetime.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		record(info.FullMethod, err, start)
+		return err
+	}
+}
+
+func record(fullMethod string, err error, start time.Time) {
+	handledTotal.WithLabelValues(fullMethod, status.Code(err).String()).Inc()
+	handledSeconds.WithLabelValues(fullMethod).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,75 @@
+// Package ratelimit provides server-side rate limiting interceptors backed
+// by a pluggable Limiter, with a token-bucket implementation provided out
+// of the box.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Limiter reports whether a new call for fullMethod is allowed to proceed.
+// Implementations may key their accounting off ctx (e.g. per-client IP or
+// auth identity) in addition to fullMethod.
+type Limiter interface {
+	Allow(ctx context.Context, fullMethod string) bool
+}
+
+// TokenBucketLimiter is a Limiter backed by a per-method token bucket, so
+// that bursts on one method don't starve others.
+type TokenBucketLimiter struct {
+	rps   rate.Limit
+	burst int
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// NewTokenBucketLimiter returns a Limiter allowing up to rps requests per
+// second per method, with bursts up to burst.
+func NewTokenBucketLimiter(rps float64, burst int) *TokenBucketLimiter {
+	return &TokenBucketLimiter{
+		rps:      rate.Limit(rps),
+		burst:    burst,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// Allow implements Limiter.
+func (l *TokenBucketLimiter) Allow(ctx context.Context, fullMethod string) bool {
+	l.mu.Lock()
+	limiter, ok := l.limiters[fullMethod]
+	if !ok {
+		limiter = rate.NewLimiter(l.rps, l.burst)
+		l.limiters[fullMethod] = limiter
+	}
+	l.mu.Unlock()
+	return limiter.Allow()
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that rejects
+// calls with codes.ResourceExhausted once limiter denies them.
+func UnaryServerInterceptor(limiter Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !limiter.Allow(ctx, info.FullMethod) {
+			return nil, status.Errorf(codes.ResourceExhausted, "ratelimit: %s is rejected by rate limiting", info.FullMethod)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// rejects streams with codes.ResourceExhausted once limiter denies them.
+func StreamServerInterceptor(limiter Limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !limiter.Allow(ss.Context(), info.FullMethod) {
+			return status.Errorf(codes.ResourceExhausted, "ratelimit: %s is rejected by rate limiting", info.FullMethod)
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,55 @@
+// Package recovery provides panic-to-error-mapping interceptors, modeled
+// after go-grpc-middleware's recovery package. Instead of letting a handler
+// panic crash the server process, it recovers the panic and returns an error
+// (by default a codes.Internal status) to the caller.
+package recovery
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// HandlerFunc maps a recovered panic value to the error returned to the
+// caller. The default wraps p as a generic codes.Internal status.
+type HandlerFunc func(ctx context.Context, p interface{}) error
+
+func defaultHandler(ctx context.Context, p interface{}) error {
+	return status.Errorf(codes.Internal, "panic triggered: %v", p)
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that
+// recovers panics raised by the handler and converts them via fn. Passing
+// a nil fn uses the default codes.Internal mapping.
+func UnaryServerInterceptor(fn HandlerFunc) grpc.UnaryServerInterceptor {
+	if fn == nil {
+		fn = defaultHandler
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fn(ctx, p)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// recovers panics raised by the handler and converts them via fn. Passing
+// a nil fn uses the default codes.Internal mapping.
+func StreamServerInterceptor(fn HandlerFunc) grpc.StreamServerInterceptor {
+	if fn == nil {
+		fn = defaultHandler
+	}
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fn(ss.Context(), p)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
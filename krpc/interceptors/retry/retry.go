@@ -0,0 +1,126 @@
+// Package retry provides a client-side retry interceptor with configurable
+// backoff and retryable status codes, modeled after go-grpc-middleware's
+// retry package.
+package retry
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BackoffFunc computes the delay before the given retry attempt (1-based).
+type BackoffFunc func(attempt uint) time.Duration
+
+// options holds the configuration built up by Option values.
+type options struct {
+	max     uint
+	backoff BackoffFunc
+	codes   map[codes.Code]bool
+}
+
+// Option configures the retry interceptor.
+type Option func(*options)
+
+// WithMax sets the maximum number of retry attempts after the initial call.
+func WithMax(max uint) Option {
+	return func(o *options) { o.max = max }
+}
+
+// WithBackoff sets the backoff strategy used between attempts. The default
+// is BackoffLinear(100 * time.Millisecond).
+func WithBackoff(fn BackoffFunc) Option {
+	return func(o *options) { o.backoff = fn }
+}
+
+// WithCodes sets the status codes that are considered retryable. The
+// default is codes.Unavailable only.
+func WithCodes(retryableCodes ...codes.Code) Option {
+	return func(o *options) {
+		o.codes = make(map[codes.Code]bool, len(retryableCodes))
+		for _, c := range retryableCodes {
+			o.codes[c] = true
+		}
+	}
+}
+
+// BackoffLinear returns a BackoffFunc that always waits d between attempts.
+func BackoffLinear(d time.Duration) BackoffFunc {
+	return func(attempt uint) time.Duration { return d }
+}
+
+// BackoffExponential returns a BackoffFunc that waits d*2^(attempt-1)
+// between attempts.
+func BackoffExponential(d time.Duration) BackoffFunc {
+	return func(attempt uint) time.Duration { return d * (1 << (attempt - 1)) }
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		max:     0,
+		backoff: BackoffLinear(100 * time.Millisecond),
+		codes:   map[codes.Code]bool{codes.Unavailable: true},
+	}
+	for _, apply := range opts {
+		apply(o)
+	}
+	return o
+}
+
+func (o *options) retryable(err error) bool {
+	return o.codes[status.Code(err)]
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that retries
+// failed unary calls according to opts.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	o := newOptions(opts...)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		var lastErr error
+		for attempt := uint(0); attempt <= o.max; attempt++ {
+			if attempt > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(o.backoff(attempt)):
+				}
+			}
+			lastErr = invoker(ctx, method, req, reply, cc, callOpts...)
+			if lastErr == nil || !o.retryable(lastErr) {
+				return lastErr
+			}
+		}
+		return lastErr
+	}
+}
+
+// StreamClientInterceptor returns a grpc.StreamClientInterceptor that
+// retries the initial Stream() call (not in-flight messages) according to
+// opts, which is sufficient for the common case of a backend that is
+// briefly unavailable at connect time.
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	o := newOptions(opts...)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		var (
+			stream grpc.ClientStream
+			lastErr error
+		)
+		for attempt := uint(0); attempt <= o.max; attempt++ {
+			if attempt > 0 {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(o.backoff(attempt)):
+				}
+			}
+			stream, lastErr = streamer(ctx, desc, cc, method, callOpts...)
+			if lastErr == nil || !o.retryable(lastErr) {
+				return stream, lastErr
+			}
+		}
+		return stream, lastErr
+	}
+}
@@ -0,0 +1,81 @@
+// Package tags provides structured correlation-id tagging for logging,
+// modeled after go-grpc-middleware's ctxtags package: each call gets a Tags
+// map seeded with its method name and request id, retrievable from the
+// handler's context and suitable for feeding into a logging interceptor.
+package tags
+
+import (
+	"context"
+
+	"github.com/gogf/gf/util/guid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type tagsKey struct{}
+
+// Tags carries per-call key/value pairs used to correlate log lines for a
+// single RPC.
+type Tags map[string]interface{}
+
+// Set stores a key/value pair on t.
+func (t Tags) Set(key string, value interface{}) {
+	t[key] = value
+}
+
+// FromContext returns the Tags stored in ctx, or an empty Tags if none were set.
+func FromContext(ctx context.Context) Tags {
+	if t, ok := ctx.Value(tagsKey{}).(Tags); ok {
+		return t
+	}
+	return Tags{}
+}
+
+// requestIdHeader is the inbound metadata key carrying a caller-supplied
+// request id; a new one is generated when absent.
+const requestIdHeader = "x-request-id"
+
+func newTags(ctx context.Context, fullMethod string) Tags {
+	requestId := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIdHeader); len(values) > 0 {
+			requestId = values[0]
+		}
+	}
+	if requestId == "" {
+		requestId = guid.S()
+	}
+	return Tags{
+		"grpc.method":     fullMethod,
+		"grpc.request_id": requestId,
+	}
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that seeds
+// the context with a new Tags map before invoking the handler.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		newCtx := context.WithValue(ctx, tagsKey{}, newTags(ctx, info.FullMethod))
+		return handler(newCtx, req)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that seeds
+// the stream's context with a new Tags map before invoking the handler.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		newCtx := context.WithValue(ss.Context(), tagsKey{}, newTags(ss.Context(), info.FullMethod))
+		return handler(srv, &tagsServerStream{ServerStream: ss, ctx: newCtx})
+	}
+}
+
+// tagsServerStream overrides Context so downstream handlers see the
+// context carrying Tags.
+type tagsServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tagsServerStream) Context() context.Context {
+	return s.ctx
+}
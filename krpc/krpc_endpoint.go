@@ -0,0 +1,62 @@
+package krpc
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// listen parses endpoint as a scheme://addr URL (e.g. "tcp://0.0.0.0:8080"
+// or "unix:///var/run/foo.sock") and opens a listener for it. Unix sockets
+// get their parent directory created and are chmod 0660 after creation,
+// which is what sidecar and CSI-style plugin deployments expect.
+func listen(endpoint string) (net.Listener, error) {
+	network, address, err := parseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if network == "unix" {
+		if err := os.MkdirAll(filepath.Dir(address), 0750); err != nil {
+			return nil, fmt.Errorf("creating parent dir for %s: %s", endpoint, err)
+		}
+		// Clear a stale socket file left behind by a previous, uncleanly
+		// stopped instance; net.Listen fails with "address already in use"
+		// otherwise.
+		if err := os.Remove(address); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("removing stale socket %s: %s", address, err)
+		}
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	if network == "unix" {
+		if err := os.Chmod(address, 0660); err != nil {
+			return nil, fmt.Errorf("chmod socket %s: %s", address, err)
+		}
+	}
+	return listener, nil
+}
+
+// parseEndpoint splits endpoint into the network and address expected by
+// net.Listen, supporting the "tcp://" and "unix://" schemes.
+func parseEndpoint(endpoint string) (network, address string, err error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid endpoint %q: %s", endpoint, err)
+	}
+	switch u.Scheme {
+	case "tcp":
+		return "tcp", u.Host, nil
+	case "unix":
+		path := u.Path
+		if path == "" {
+			path = u.Opaque
+		}
+		return "unix", path, nil
+	default:
+		return "", "", fmt.Errorf("unsupported endpoint scheme %q in %q, want tcp:// or unix://", u.Scheme, endpoint)
+	}
+}
@@ -0,0 +1,90 @@
+package krpc
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"github.com/soheilhy/cmux"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+)
+
+// prepareEndpoint builds whatever is needed to serve listener and returns
+// the function Start should run in that listener's goroutine. When
+// GrpcServerConfig.EnableGrpcWeb or HTTPGateway is set, gRPC, gRPC-Web and
+// the REST/JSON gateway registered through HTTPGateway are multiplexed onto
+// listener via cmux instead of requiring a separate Envoy/gateway process;
+// the resulting http.Server and the root listener are recorded on s so
+// drain/ForceStop can shut them down alongside the gRPC server. Otherwise
+// it falls back to a plain s.Server.Serve.
+func (s *GrpcServer) prepareEndpoint(listener net.Listener) func() error {
+	if !s.config.EnableGrpcWeb && s.config.HTTPGateway == nil {
+		return func() error {
+			return ignoreErr(s.Server.Serve(listener), grpc.ErrServerStopped)
+		}
+	}
+
+	m := cmux.New(listener)
+	grpcListener := m.MatchWithWriters(cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc"))
+	httpListener := m.Match(cmux.HTTP1Fast(), cmux.HTTP2())
+
+	httpServer := &http.Server{Handler: h2c.NewHandler(s.gatewayHandler(), &http2.Server{})}
+
+	// Closing listener (the root cmux listener) is what stops mux.Serve;
+	// drain/ForceStop need it alongside httpServer to fully release the port.
+	s.httpServers = append(s.httpServers, httpServer)
+	s.muxListeners = append(s.muxListeners, listener)
+
+	return func() error {
+		errChan := make(chan error, 3)
+		go func() { errChan <- ignoreErr(s.Server.Serve(grpcListener), grpc.ErrServerStopped) }()
+		go func() { errChan <- ignoreErr(httpServer.Serve(httpListener), http.ErrServerClosed) }()
+		go func() { errChan <- ignoreErr(m.Serve(), cmux.ErrListenerClosed) }()
+		return <-errChan
+	}
+}
+
+// ignoreErr returns nil in place of target, e.g. the sentinel errors
+// returned by Serve methods on a clean, expected shutdown.
+func ignoreErr(err, target error) error {
+	if err == target {
+		return nil
+	}
+	return err
+}
+
+// gatewayHandler builds the http.Handler installed on the multiplexed HTTP
+// listener: browser-compatible gRPC-Web framing, with CORS, falling
+// through to the user-registered HTTPGateway mux for everything else.
+func (s *GrpcServer) gatewayHandler() http.Handler {
+	next := http.Handler(http.NotFoundHandler())
+	if s.config.HTTPGateway != nil {
+		next = s.config.HTTPGateway
+	}
+	if !s.config.EnableGrpcWeb {
+		return next
+	}
+	wrapped := grpcweb.WrapServer(s.Server,
+		grpcweb.WithOriginFunc(s.grpcWebOriginAllowed),
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wrapped.IsGrpcWebRequest(r) || wrapped.IsAcceptableGrpcCorsRequest(r) {
+			wrapped.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// grpcWebOriginAllowed decides whether a cross-origin gRPC-Web request is
+// allowed. It defers to GrpcServerConfig.GrpcWebAllowedOrigin when set; with
+// no hook configured it denies every cross-origin request, so EnableGrpcWeb
+// doesn't silently reflect an allow-all CORS policy back at the browser.
+func (s *GrpcServer) grpcWebOriginAllowed(origin string) bool {
+	if s.config.GrpcWebAllowedOrigin == nil {
+		return false
+	}
+	return s.config.GrpcWebAllowedOrigin(origin)
+}
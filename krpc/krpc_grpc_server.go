@@ -1,6 +1,7 @@
 package krpc
 
 import (
+	"context"
 	"fmt"
 	"github.com/gogf/gf/frame/g"
 	"github.com/gogf/gf/net/gipv4"
@@ -9,8 +10,16 @@ import (
 	"github.com/gogf/gf/os/gproc"
 	"github.com/gogf/gf/text/gstr"
 	"github.com/gogf/katyusha/discovery"
+	"github.com/gogf/katyusha/krpc/interceptors/auth"
+	"github.com/gogf/katyusha/krpc/interceptors/prometheus"
+	"github.com/gogf/katyusha/krpc/interceptors/ratelimit"
+	"github.com/gogf/katyusha/krpc/interceptors/recovery"
+	"github.com/gogf/katyusha/krpc/interceptors/tags"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
@@ -18,13 +27,22 @@ import (
 	"time"
 )
 
+// defaultShutdownDrainTimeout is used when GrpcServerConfig.ShutdownDrainTimeout
+// is left at its zero value.
+const defaultShutdownDrainTimeout = 10 * time.Second
+
 // GrpcServer is the server for GRPC protocol.
 type GrpcServer struct {
-	Server    *grpc.Server
-	Logger    *glog.Logger
-	config    *GrpcServerConfig
-	services  []*discovery.Service
-	waitGroup sync.WaitGroup
+	Server             *grpc.Server
+	Logger             *glog.Logger
+	config             *GrpcServerConfig
+	services           []*discovery.Service
+	waitGroup          sync.WaitGroup
+	interceptors       *serviceInterceptors
+	health             *health.Server
+	registeredServices []string
+	httpServers        []*http.Server
+	muxListeners       []net.Listener
 }
 
 // NewGrpcServer creates and returns a grpc server.
@@ -45,17 +63,48 @@ func (s *krpcServer) NewGrpcServer(conf ...*GrpcServerConfig) *GrpcServer {
 		config.Logger = glog.New()
 	}
 	server := &GrpcServer{
-		Logger: config.Logger,
-		config: config,
+		Logger:       config.Logger,
+		config:       config,
+		interceptors: newServiceInterceptors(),
+		health:       health.NewServer(),
+	}
+	var (
+		unaryInterceptors  = []grpc.UnaryServerInterceptor{server.UnaryError, server.UnaryLogger, server.UnaryRecover}
+		streamInterceptors = []grpc.StreamServerInterceptor{}
+	)
+	// Batteries-included interceptors from krpc/interceptors, enabled purely
+	// through config flags so callers don't have to hand-chain them.
+	if config.EnableTags {
+		unaryInterceptors = append(unaryInterceptors, tags.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, tags.StreamServerInterceptor())
+	}
+	if config.Auth != nil {
+		unaryInterceptors = append(unaryInterceptors, auth.UnaryServerInterceptor(config.Auth))
+		streamInterceptors = append(streamInterceptors, auth.StreamServerInterceptor(config.Auth))
+	}
+	if config.RateLimiter != nil {
+		unaryInterceptors = append(unaryInterceptors, ratelimit.UnaryServerInterceptor(config.RateLimiter))
+		streamInterceptors = append(streamInterceptors, ratelimit.StreamServerInterceptor(config.RateLimiter))
 	}
+	if config.EnablePrometheus {
+		unaryInterceptors = append(unaryInterceptors, prometheus.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, prometheus.StreamServerInterceptor())
+	}
+	if config.EnableRecovery {
+		unaryInterceptors = append(unaryInterceptors, recovery.UnaryServerInterceptor(config.RecoveryHandler))
+		streamInterceptors = append(streamInterceptors, recovery.StreamServerInterceptor(config.RecoveryHandler))
+	}
+	// Per-service interceptors are discovered at RegisterService time, so
+	// this dispatcher must be chained in last: global config interceptors
+	// above run first, then whatever the dispatched service declared.
+	unaryInterceptors = append(unaryInterceptors, server.interceptors.dispatchUnary)
+	streamInterceptors = append(streamInterceptors, server.interceptors.dispatchStream)
 	server.config.Options = append([]grpc.ServerOption{
-		s.ChainUnary(
-			server.UnaryError,
-			server.UnaryLogger,
-			server.UnaryRecover,
-		),
+		s.ChainUnary(unaryInterceptors...),
+		s.ChainStream(streamInterceptors...),
 	}, server.config.Options...)
 	server.Server = grpc.NewServer(server.config.Options...)
+	grpc_health_v1.RegisterHealthServer(server.Server, server.health)
 	return server
 }
 
@@ -83,11 +132,16 @@ func (s *GrpcServer) Service(services ...*discovery.Service) {
 	s.services = services
 }
 
-// Run starts the server in blocking way.
-func (s *GrpcServer) Run() {
-	listener, err := net.Listen("tcp", s.config.Address)
-	if err != nil {
-		s.Logger.Fatal(err)
+// Start starts the server in a non-blocking way, listening concurrently on
+// every endpoint (e.g. "tcp://0.0.0.0:8080", "unix:///var/run/foo.sock").
+// Each endpoint gets its own listener goroutine, but all of them share this
+// server's interceptor chain and service registrations. Calling Start with
+// no endpoints falls back to GrpcServerConfig.Address over tcp, matching
+// the server's previous single-listener behavior. Use Wait to block until
+// every listener has stopped, and Stop/ForceStop to shut them all down.
+func (s *GrpcServer) Start(endpoints ...string) {
+	if len(endpoints) == 0 {
+		endpoints = []string{"tcp://" + s.config.Address}
 	}
 	if len(s.services) == 0 {
 		appId := gcmd.GetWithEnv(discovery.EnvKeyAppId).String()
@@ -99,21 +153,49 @@ func (s *GrpcServer) Run() {
 			})
 		}
 	}
-	// Start listening.
-	go func() {
-		if err := s.Server.Serve(listener); err != nil {
+
+	listeners := make([]net.Listener, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		listener, err := listen(endpoint)
+		if err != nil {
 			s.Logger.Fatal(err)
 		}
-	}()
+		listeners = append(listeners, listener)
+	}
+
+	// Built up-front (not inside the goroutines below) so that the
+	// http.Server/listener bookkeeping prepareEndpoint does is visible to
+	// drain/ForceStop as soon as Start returns, with no risk of a race
+	// against a signal arriving mid-setup.
+	serveFuncs := make([]func() error, 0, len(listeners))
+	for _, listener := range listeners {
+		serveFuncs = append(serveFuncs, s.prepareEndpoint(listener))
+	}
+	for _, serve := range serveFuncs {
+		serve := serve
+		s.waitGroup.Add(1)
+		go func() {
+			defer s.waitGroup.Done()
+			if err := serve(); err != nil {
+				s.Logger.Fatal(err)
+			}
+		}()
+	}
 
-	// Register service list after server starts.
+	// Register service list after the listeners are up.
 	for _, service := range s.services {
-		if err = discovery.Register(service); err != nil {
+		if err := discovery.Register(service); err != nil {
 			s.Logger.Fatal(err)
 		}
 	}
 
-	s.Logger.Printf("grpc server start listening on: %s, pid: %d", s.config.Address, gproc.Pid())
+	// Now that every listener is actually up, flip every registered service
+	// to SERVING so health checks (and thus load balancers) start passing.
+	for _, service := range s.registeredServices {
+		s.health.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_SERVING)
+	}
+
+	s.Logger.Printf("grpc server start listening on: %v, pid: %d", endpoints, gproc.Pid())
 
 	// Signal listening and handling for gracefully shutdown.
 	sigChan := make(chan os.Signal, 1)
@@ -121,47 +203,100 @@ func (s *GrpcServer) Run() {
 		sigChan,
 		syscall.SIGINT,
 		syscall.SIGQUIT,
-		syscall.SIGKILL,
 		syscall.SIGTERM,
 		syscall.SIGABRT,
 	)
-	for {
-		switch <-sigChan {
-		case
-			syscall.SIGINT,
-			syscall.SIGQUIT,
-			syscall.SIGKILL,
-			syscall.SIGTERM,
-			syscall.SIGABRT:
-			s.Logger.Print("gracefully shutting down")
-			for _, service := range s.services {
-				discovery.Unregister(service)
-			}
-			time.Sleep(time.Second)
-			s.Stop()
-			return
-		default:
-		}
-	}
+	go func() {
+		sig := <-sigChan
+		s.Logger.Printf("received signal %s, gracefully shutting down", sig)
+		s.Stop()
+	}()
 }
 
-// Start starts the server in no-blocking way.
-func (s *GrpcServer) Start() {
-	s.waitGroup.Add(1)
+// drain runs the shutdown sequence common to signal receipt and an explicit
+// Stop call: it flips health to NOT_SERVING for every registered service so
+// upstream load balancers stop routing new work, unregisters from
+// discovery, then waits up to GrpcServerConfig.ShutdownDrainTimeout for the
+// gRPC server and any HTTP gateways from prepareEndpoint to drain their
+// in-flight calls before forcing termination.
+func (s *GrpcServer) drain() {
+	for _, service := range s.registeredServices {
+		s.health.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	}
+	for _, service := range s.services {
+		discovery.Unregister(service)
+	}
+	timeout := s.config.ShutdownDrainTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownDrainTimeout
+	}
+	drained := make(chan struct{})
 	go func() {
-		defer s.waitGroup.Done()
-		s.Run()
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		for _, httpServer := range s.httpServers {
+			httpServer.Shutdown(ctx)
+		}
+		s.Server.GracefulStop()
+		// Closing the root listener behind each cmux is what stops its
+		// mux.Serve goroutine; GracefulStop only closes the grpc-matched
+		// sub-listener.
+		for _, listener := range s.muxListeners {
+			listener.Close()
+		}
+		close(drained)
 	}()
+	select {
+	case <-drained:
+	case <-time.After(timeout):
+		s.Logger.Printf("shutdown drain timeout of %s elapsed, forcing stop", timeout)
+		s.forceStop()
+	}
 }
 
-// Wait works with Start, which blocks current goroutine until the server stops.
+// Wait works with Start, blocking the current goroutine until every
+// listener Start opened has stopped serving.
 func (s *GrpcServer) Wait() {
 	s.waitGroup.Wait()
 }
 
-// Stop gracefully stops the server.
+// Stop gracefully stops the server, running the same health-flip, discovery
+// unregister and drain-then-force sequence used on signal receipt.
 func (s *GrpcServer) Stop() {
-	s.Server.GracefulStop()
+	s.drain()
+}
+
+// ForceStop immediately terminates the server without waiting for in-flight
+// RPCs to finish, for callers that can't afford Stop's drain window at all
+// (e.g. a second signal after a graceful shutdown is already under way).
+func (s *GrpcServer) ForceStop() {
+	for _, service := range s.registeredServices {
+		s.health.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	}
+	for _, service := range s.services {
+		discovery.Unregister(service)
+	}
+	s.forceStop()
+}
+
+// forceStop immediately tears down the gRPC server, any HTTP gateways from
+// prepareEndpoint, and their cmux root listeners. Shared by ForceStop and
+// drain's timeout path.
+func (s *GrpcServer) forceStop() {
+	for _, httpServer := range s.httpServers {
+		httpServer.Close()
+	}
+	s.Server.Stop()
+	for _, listener := range s.muxListeners {
+		listener.Close()
+	}
+}
+
+// SetServingStatus toggles the health status reported for service, letting
+// applications flip readiness during long-running init or maintenance
+// without tearing the server down.
+func (s *GrpcServer) SetServingStatus(service string, status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	s.health.SetServingStatus(service, status)
 }
 
 // ChainUnary returns a ServerOption that specifies the chained interceptor
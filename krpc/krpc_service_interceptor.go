@@ -0,0 +1,118 @@
+package krpc
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// UnaryServerInterceptor is implemented by a service implementation that
+// ships its own unary middleware (per-service auth, defaulting, validation
+// overrides) alongside its handlers. Implementations registered through
+// GrpcServer.RegisterService are discovered automatically and chained into
+// the server's pipeline for RPCs dispatched to that service, so main.go
+// doesn't need to know the middleware exists.
+type UnaryServerInterceptor interface {
+	UnaryServerInterceptor() grpc.UnaryServerInterceptor
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor.
+type StreamServerInterceptor interface {
+	StreamServerInterceptor() grpc.StreamServerInterceptor
+}
+
+// NoopInterceptor can be embedded in a service implementation so it
+// trivially satisfies UnaryServerInterceptor and StreamServerInterceptor,
+// letting the struct opt in to only the one it actually overrides.
+type NoopInterceptor struct{}
+
+// UnaryServerInterceptor implements UnaryServerInterceptor with a pass-through.
+func (NoopInterceptor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor implements StreamServerInterceptor with a pass-through.
+func (NoopInterceptor) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, ss)
+	}
+}
+
+// serviceInterceptors holds the per-service interceptors discovered at
+// RegisterService time, keyed by gRPC service name (e.g. "basic.Echo").
+// A single instance backs the dispatchUnary/dispatchStream interceptors
+// that NewGrpcServer chains in last, so per-service middleware always runs
+// after the server's global config interceptors.
+type serviceInterceptors struct {
+	mu     sync.RWMutex
+	unary  map[string]grpc.UnaryServerInterceptor
+	stream map[string]grpc.StreamServerInterceptor
+}
+
+func newServiceInterceptors() *serviceInterceptors {
+	return &serviceInterceptors{
+		unary:  make(map[string]grpc.UnaryServerInterceptor),
+		stream: make(map[string]grpc.StreamServerInterceptor),
+	}
+}
+
+// discover records impl's interceptors, if any, under service.
+func (r *serviceInterceptors) discover(service string, impl interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u, ok := impl.(UnaryServerInterceptor); ok {
+		r.unary[service] = u.UnaryServerInterceptor()
+	}
+	if st, ok := impl.(StreamServerInterceptor); ok {
+		r.stream[service] = st.StreamServerInterceptor()
+	}
+}
+
+func serviceNameOf(fullMethod string) string {
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return strings.TrimPrefix(fullMethod[:i], "/")
+	}
+	return fullMethod
+}
+
+// dispatchUnary looks up and runs whichever per-service interceptor was
+// discovered for the called service, falling through to handler when none
+// was registered.
+func (r *serviceInterceptors) dispatchUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	r.mu.RLock()
+	interceptor, ok := r.unary[serviceNameOf(info.FullMethod)]
+	r.mu.RUnlock()
+	if !ok {
+		return handler(ctx, req)
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+// dispatchStream is the streaming counterpart of dispatchUnary.
+func (r *serviceInterceptors) dispatchStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	r.mu.RLock()
+	interceptor, ok := r.stream[serviceNameOf(info.FullMethod)]
+	r.mu.RUnlock()
+	if !ok {
+		return handler(srv, ss)
+	}
+	return interceptor(srv, ss, info, handler)
+}
+
+// RegisterService implements grpc.ServiceRegistrar. Pass s itself (instead
+// of s.Server) to generated RegisterXServer functions so that katyusha can
+// auto-discover interceptors declared on impl before delegating
+// registration to the underlying *grpc.Server. The service starts out
+// NOT_SERVING on the health server; Start flips it to SERVING once the
+// listener is actually up.
+func (s *GrpcServer) RegisterService(desc *grpc.ServiceDesc, impl interface{}) {
+	s.interceptors.discover(desc.ServiceName, impl)
+	s.registeredServices = append(s.registeredServices, desc.ServiceName)
+	s.health.SetServingStatus(desc.ServiceName, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	s.Server.RegisterService(desc, impl)
+}
@@ -0,0 +1,51 @@
+package proxy
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// rawCodecName is the content-subtype the proxy requests (via
+// grpc.CallContentSubtype) on its backend streams to have frames pass
+// through rawCodec untouched instead of being unmarshaled into protobuf
+// messages. The proxy's own server side does not negotiate it; it is forced
+// onto rawCodec by Proxy.ServerOptions.
+const rawCodecName = "proxy"
+
+// frame is an already-encoded gRPC message. rawCodec copies bytes in and
+// out of it without ever looking at the backend's protobuf types, which is
+// what lets Proxy forward methods it knows nothing about.
+type frame struct {
+	payload []byte
+}
+
+// rawCodec implements encoding.Codec and is registered globally under
+// rawCodecName so streams created with grpc.CallContentSubtype(rawCodecName)
+// skip marshaling entirely.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	f, ok := v.(*frame)
+	if !ok {
+		return nil, fmt.Errorf("proxy: codec expects *frame, got %T", v)
+	}
+	return f.payload, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	f, ok := v.(*frame)
+	if !ok {
+		return fmt.Errorf("proxy: codec expects *frame, got %T", v)
+	}
+	f.payload = data
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return rawCodecName
+}
+
+func init() {
+	encoding.RegisterCodec(rawCodec{})
+}
@@ -0,0 +1,163 @@
+// Package proxy implements a Praefect-style transparent gRPC reverse proxy
+// on top of katyusha's discovery layer. A Proxy is installed on a
+// *grpc.Server via ServerOptions as its UnknownServiceHandler, forced onto a
+// raw passthrough codec: any method the server has no local handler for is
+// streamed through, frame-for-frame and without unmarshaling, to a backend
+// chosen by a pluggable Router. This makes it possible to build sharding,
+// canarying and read/write-splitting gateways in front of ordinary katyusha
+// services. Because the raw codec is forced server-wide, a *grpc.Server
+// configured with ServerOptions can only proxy — it cannot also host
+// locally-registered proto services on the same listener.
+package proxy
+
+import (
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Proxy forwards methods it does not recognize to a backend selected by
+// Router, preserving inbound metadata and streaming both directions
+// concurrently.
+type Proxy struct {
+	router Router
+}
+
+// New creates and returns a Proxy that forwards unknown methods through router.
+func New(router Router) *Proxy {
+	return &Proxy{router: router}
+}
+
+// ServerOptions returns the grpc.ServerOptions that install the proxy as the
+// server's unknown service handler. Pass them to GrpcServerConfig.Options so
+// that NewGrpcServer picks them up alongside katyusha's own interceptors.
+//
+// Unlike the client side, an ordinary caller has no way to request the raw
+// codec via CallContentSubtype, so the server must be forced onto it with
+// grpc.ForceServerCodec; without it the server negotiates the default proto
+// codec and RecvMsg(&frame{}) fails immediately. grpc.ForceServerCodec makes
+// rawCodec the only codec the server will ever use, so a *grpc.Server with
+// these options cannot also serve locally-registered proto services over
+// the same listener — Proxy is for proxy-only servers.
+func (p *Proxy) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(p.handleStream),
+	}
+}
+
+// proxyStreamDesc describes the generic bidi stream used to talk to
+// backends, regardless of the real method's streaming shape.
+var proxyStreamDesc = &grpc.StreamDesc{
+	ServerStreams: true,
+	ClientStreams: true,
+}
+
+// handleStream is installed as the server's UnknownServiceHandler. It opens
+// a client stream to the backend chosen by Router and pumps raw frames in
+// both directions until the backend call completes, returning its header,
+// trailer and status verbatim to the caller.
+func (p *Proxy) handleStream(srv interface{}, serverStream grpc.ServerStream) error {
+	fullMethod, ok := grpc.MethodFromServerStream(serverStream)
+	if !ok {
+		return status.Error(codes.Internal, "proxy: unable to determine full method name")
+	}
+
+	ctx := serverStream.Context()
+	cc, err := p.router.Route(ctx, fullMethod)
+	if err != nil {
+		return err
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = metadata.NewOutgoingContext(ctx, md.Copy())
+	}
+
+	clientStream, err := grpc.NewClientStream(ctx, proxyStreamDesc, cc, fullMethod, grpc.CallContentSubtype(rawCodecName))
+	if err != nil {
+		return err
+	}
+
+	backendErrChan := forwardBackendToCaller(clientStream, serverStream)
+	callerErrChan := forwardCallerToBackend(serverStream, clientStream)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-backendErrChan:
+			// The backend leg finishing is always what decides the call's
+			// outcome, whether or not the caller->backend leg is done yet:
+			// io.EOF means the backend completed with an OK status, and
+			// any other error is already the verbatim status to return.
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		case err := <-callerErrChan:
+			if err != io.EOF {
+				return err
+			}
+			// The caller is done sending; half-close so the backend sees
+			// it, and keep waiting on backendErrChan for the real outcome.
+			clientStream.CloseSend()
+		}
+	}
+	return status.Error(codes.Internal, "proxy: unreachable")
+}
+
+// forwardBackendToCaller copies frames from clientStream (the backend) to
+// serverStream (the caller). It forwards the backend's response header
+// before the first frame and its trailer once the backend call completes,
+// so neither is silently dropped the way copying only message frames
+// would be.
+func forwardBackendToCaller(clientStream grpc.ClientStream, serverStream grpc.ServerStream) chan error {
+	errChan := make(chan error, 1)
+	go func() {
+		var err error
+		// The trailer is set before err is reported, so it is already on
+		// serverStream by the time handleStream returns.
+		defer func() {
+			serverStream.SetTrailer(clientStream.Trailer())
+			errChan <- err
+		}()
+		var header metadata.MD
+		if header, err = clientStream.Header(); err != nil {
+			return
+		}
+		if err = serverStream.SetHeader(header); err != nil {
+			return
+		}
+		for {
+			f := &frame{}
+			if err = clientStream.RecvMsg(f); err != nil {
+				return
+			}
+			if err = serverStream.SendMsg(f); err != nil {
+				return
+			}
+		}
+	}()
+	return errChan
+}
+
+// forwardCallerToBackend copies frames from serverStream (the caller) to
+// clientStream (the backend).
+func forwardCallerToBackend(serverStream grpc.ServerStream, clientStream grpc.ClientStream) chan error {
+	errChan := make(chan error, 1)
+	go func() {
+		for {
+			f := &frame{}
+			if err := serverStream.RecvMsg(f); err != nil {
+				errChan <- err
+				return
+			}
+			if err := clientStream.SendMsg(f); err != nil {
+				errChan <- err
+				return
+			}
+		}
+	}()
+	return errChan
+}
@@ -0,0 +1,59 @@
+package proxy
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/gogf/katyusha/discovery"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errNoBackends is returned by routers that have no healthy backend to
+// forward a call to.
+var errNoBackends = status.Error(codes.Unavailable, "proxy: no backends available")
+
+// Router selects the backend connection that a given call should be
+// forwarded to. Implementations typically inspect the context (metadata,
+// peer info) and fullMethod to apply primary/secondary selection,
+// method-level routing or health filtering over the services registered in
+// discovery.
+type Router interface {
+	// Route returns the client connection to the backend that should serve
+	// fullMethod for the given context.
+	Route(ctx context.Context, fullMethod string) (*grpc.ClientConn, error)
+}
+
+// RoundRobinRouter is a Router that load-balances across a fixed backend
+// set resolved from discovery.Service, ignoring fullMethod. It is intended
+// as a simple default; sharding, canarying or read/write-splitting gateways
+// typically layer per-method selection on top of the same discovery data by
+// implementing Router directly.
+type RoundRobinRouter struct {
+	conns []*grpc.ClientConn
+	next  uint64
+}
+
+// NewRoundRobinRouter dials every service address in services and returns a
+// Router that round-robins across them.
+func NewRoundRobinRouter(services []*discovery.Service, opts ...grpc.DialOption) (*RoundRobinRouter, error) {
+	router := &RoundRobinRouter{}
+	for _, service := range services {
+		conn, err := grpc.Dial(service.Address, opts...)
+		if err != nil {
+			return nil, err
+		}
+		router.conns = append(router.conns, conn)
+	}
+	return router, nil
+}
+
+// Route implements Router.
+func (r *RoundRobinRouter) Route(ctx context.Context, fullMethod string) (*grpc.ClientConn, error) {
+	if len(r.conns) == 0 {
+		return nil, errNoBackends
+	}
+	i := atomic.AddUint64(&r.next, 1)
+	return r.conns[i%uint64(len(r.conns))], nil
+}